@@ -0,0 +1,115 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DEFAULT_CACHE_CAPACITY is the default entry count of NewMemoryCache
+const DEFAULT_CACHE_CAPACITY = 256
+
+// Cache is a pluggable cache for whois query results, keyed by whois
+// server and query. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the cached data for key, and whether it was found and
+	// still within its TTL
+	Get(key string) ([]byte, bool)
+	// Set stores data under key, valid for ttl
+	Set(key string, data []byte, ttl time.Duration)
+}
+
+// memoryCache is the default in-memory LRU Cache implementation
+type memoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+// cacheEntry is a single memoryCache entry
+type cacheEntry struct {
+	key     string
+	data    []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns an in-memory LRU Cache holding up to capacity
+// entries, or DEFAULT_CACHE_CAPACITY entries if capacity is not positive
+func NewMemoryCache(capacity int) Cache {
+	if capacity <= 0 {
+		capacity = DEFAULT_CACHE_CAPACITY
+	}
+
+	return &memoryCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get implements Cache
+func (m *memoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		m.order.Remove(el)
+		delete(m.items, key)
+		return nil, false
+	}
+
+	m.order.MoveToFront(el)
+
+	return entry.data, true
+}
+
+// Set implements Cache
+func (m *memoryCache) Set(key string, data []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		entry.data = data
+		entry.expires = time.Now().Add(ttl)
+		m.order.MoveToFront(el)
+		return
+	}
+
+	el := m.order.PushFront(&cacheEntry{key: key, data: data, expires: time.Now().Add(ttl)})
+	m.items[key] = el
+
+	if m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		if oldest != nil {
+			m.order.Remove(oldest)
+			delete(m.items, oldest.Value.(*cacheEntry).key)
+		}
+	}
+}
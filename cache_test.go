@@ -0,0 +1,67 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheGetSet(t *testing.T) {
+	c := NewMemoryCache(2)
+
+	if _, ok := c.Get("missing"); ok {
+		t.Error("Get on an empty cache returned ok = true")
+	}
+
+	c.Set("a", []byte("1"), time.Minute)
+	data, ok := c.Get("a")
+	if !ok || string(data) != "1" {
+		t.Errorf("Get(\"a\") = %q, %v, want \"1\", true", data, ok)
+	}
+}
+
+func TestMemoryCacheExpires(t *testing.T) {
+	c := NewMemoryCache(2)
+
+	c.Set("a", []byte("1"), -time.Second)
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get returned an entry past its TTL")
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewMemoryCache(2)
+
+	c.Set("a", []byte("1"), time.Minute)
+	c.Set("b", []byte("2"), time.Minute)
+	c.Get("a")
+	c.Set("c", []byte("3"), time.Minute)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(\"b\") found an entry, want it evicted as least recently used")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("Get(\"a\") not found, want it kept as recently used")
+	}
+	if _, ok := c.Get("c"); !ok {
+		t.Error("Get(\"c\") not found, want the just-inserted entry kept")
+	}
+}
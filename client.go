@@ -0,0 +1,403 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// DEFAULT_TIMEOUT is default read/write timeout for a whois query
+const DEFAULT_TIMEOUT = time.Second * 30
+
+// Client is a whois client, it holds the connection options used to
+// perform whois queries, such as the dialer, timeout and whois port
+type Client struct {
+	dialer               proxy.Dialer
+	timeout              time.Duration
+	whoisServer          string
+	whoisPort            string
+	disableReferralChain bool
+
+	mode          WhoisMode
+	httpClient    *http.Client
+	bootstrapTTL  time.Duration
+	bootstrapMu   sync.Mutex
+	bootstrapData map[string]*rdapBootstrapEntry
+
+	serverMap map[string]string
+	profiles  map[string]*ServerProfile
+
+	rateLimits map[string]RateLimit
+	buckets    map[string]*tokenBucket
+	bucketsMu  sync.Mutex
+
+	cache    Cache
+	cacheTTL time.Duration
+}
+
+// DEFAULT_CACHE_TTL is the default TTL applied to cached whois results
+const DEFAULT_CACHE_TTL = time.Minute * 10
+
+// NewClient returns a new whois client with the default options. Caching
+// is disabled until SetCache is called; rate limiting uses DefaultRateLimits.
+func NewClient() *Client {
+	return &Client{
+		dialer:       proxy.Direct,
+		timeout:      DEFAULT_TIMEOUT,
+		whoisServer:  IANA_WHOIS_SERVER,
+		whoisPort:    DEFAULT_WHOIS_PORT,
+		mode:         ModeWhoisOnly,
+		httpClient:   &http.Client{Timeout: DEFAULT_TIMEOUT},
+		bootstrapTTL: DEFAULT_RDAP_BOOTSTRAP_TTL,
+		serverMap:    DefaultServers,
+		profiles:     DefaultServerProfiles,
+		rateLimits:   DefaultRateLimits,
+		cacheTTL:     DEFAULT_CACHE_TTL,
+	}
+}
+
+// SetTimeout sets the read/write timeout used for whois queries, and the
+// request timeout used for RDAP queries
+func (c *Client) SetTimeout(timeout time.Duration) *Client {
+	c.timeout = timeout
+	c.httpClient.Timeout = timeout
+	return c
+}
+
+// SetDialer sets a custom dialer for the client, it can be used to route
+// whois queries through a SOCKS5 or HTTP proxy, or to bind a source IP
+func (c *Client) SetDialer(dialer proxy.Dialer) *Client {
+	c.dialer = dialer
+	return c
+}
+
+// SetWhoisServer sets the iana whois server used to discover the
+// authoritative whois server for a given domain
+func (c *Client) SetWhoisServer(server string) *Client {
+	c.whoisServer = server
+	return c
+}
+
+// SetWhoisPort sets the whois port used to connect to whois servers
+func (c *Client) SetWhoisPort(port string) *Client {
+	c.whoisPort = port
+	return c
+}
+
+// SetDisableReferralChain disables following the referral whois server
+// returned in the initial whois response
+func (c *Client) SetDisableReferralChain(disable bool) *Client {
+	c.disableReferralChain = disable
+	return c
+}
+
+// SetMode sets the WhoisMode used by Whois to decide between the legacy
+// port-43 whois protocol and RDAP
+func (c *Client) SetMode(mode WhoisMode) *Client {
+	c.mode = mode
+	return c
+}
+
+// SetRDAPBootstrapTTL sets how long an IANA RDAP bootstrap file is cached
+// in memory before being re-fetched
+func (c *Client) SetRDAPBootstrapTTL(ttl time.Duration) *Client {
+	c.bootstrapTTL = ttl
+	return c
+}
+
+// SetServerMap replaces the client's TLD to whois server map wholesale,
+// it defaults to DefaultServers
+func (c *Client) SetServerMap(servers map[string]string) *Client {
+	c.serverMap = servers
+	return c
+}
+
+// AddServer adds or overrides a single TLD to whois server mapping,
+// without affecting DefaultServers or other clients sharing it
+func (c *Client) AddServer(tld, server string) *Client {
+	m := make(map[string]string, len(c.serverMap)+1)
+	for k, v := range c.serverMap {
+		m[k] = v
+	}
+	m[strings.ToLower(tld)] = server
+	c.serverMap = m
+
+	return c
+}
+
+// lookupServer returns the authoritative whois server for domain from the
+// client's server map, preferring the longest matching suffix so
+// multi-label SLDs like "co.uk" take priority over "uk"
+func (c *Client) lookupServer(domain string) (string, bool) {
+	labels := strings.Split(strings.ToLower(domain), ".")
+	for i := 1; i < len(labels); i++ {
+		if server, ok := c.serverMap[strings.Join(labels[i:], ".")]; ok {
+			return server, true
+		}
+	}
+
+	return "", false
+}
+
+// SetServerProfile adds or overrides the ServerProfile used for a whois
+// host, without affecting DefaultServerProfiles or other clients sharing it
+func (c *Client) SetServerProfile(server string, profile *ServerProfile) *Client {
+	m := make(map[string]*ServerProfile, len(c.profiles)+1)
+	for k, v := range c.profiles {
+		m[k] = v
+	}
+	m[strings.ToLower(server)] = profile
+	c.profiles = m
+
+	return c
+}
+
+// profileFor returns the ServerProfile registered for server, if any
+func (c *Client) profileFor(server string) *ServerProfile {
+	return c.profiles[strings.ToLower(server)]
+}
+
+// SetCache sets the Cache used to serve repeated queries within their TTL,
+// it is nil (disabled) by default
+func (c *Client) SetCache(cache Cache) *Client {
+	c.cache = cache
+	return c
+}
+
+// SetCacheTTL sets how long a cached result stays fresh
+func (c *Client) SetCacheTTL(ttl time.Duration) *Client {
+	c.cacheTTL = ttl
+	return c
+}
+
+// Whois do the whois query and returns whois info, following the mode
+// set with SetMode to decide between the legacy whois protocol and RDAP
+func (c *Client) Whois(domain string, servers ...string) (result string, err error) {
+	domain = strings.Trim(strings.TrimSpace(domain), ".")
+	if domain == "" {
+		err = fmt.Errorf("whois: domain is empty")
+		return
+	}
+
+	switch c.mode {
+	case ModeRDAPOnly:
+		return c.rdapText(domain)
+	case ModeWhoisFirst:
+		result, err = c.legacyWhois(domain, servers...)
+		if err == nil {
+			return
+		}
+		return c.rdapText(domain)
+	case ModeRDAPFirst:
+		result, err = c.rdapText(domain)
+		if err == nil {
+			return
+		}
+		return c.legacyWhois(domain, servers...)
+	case ModePreferBoth:
+		return c.whoisAndRDAP(domain, servers...)
+	default:
+		return c.legacyWhois(domain, servers...)
+	}
+}
+
+// legacyWhois do the port-43 whois query and returns whois info, it is a
+// thin wrapper around WhoisFull, returning just the raw response, except
+// when servers pins an explicit server and normalization should be skipped
+func (c *Client) legacyWhois(domain string, servers ...string) (result string, err error) {
+	if len(servers) > 0 && servers[0] != "" {
+		_, result, err = c.query(domain, servers...)
+		return
+	}
+
+	full, err := c.WhoisFull(domain)
+	if err != nil {
+		return "", err
+	}
+
+	return full.Raw, nil
+}
+
+// whoisAndRDAP queries both RDAP and the legacy whois protocol concurrently
+// and merges their output, used by ModePreferBoth. It only fails if both
+// queries fail; a single success is returned on its own.
+func (c *Client) whoisAndRDAP(domain string, servers ...string) (string, error) {
+	var rdapData, whoisData string
+	var rdapErr, whoisErr error
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		rdapData, rdapErr = c.rdapText(domain)
+	}()
+	go func() {
+		defer wg.Done()
+		whoisData, whoisErr = c.legacyWhois(domain, servers...)
+	}()
+	wg.Wait()
+
+	switch {
+	case rdapErr == nil && whoisErr == nil:
+		return rdapData + whoisData, nil
+	case rdapErr == nil:
+		return rdapData, nil
+	case whoisErr == nil:
+		return whoisData, nil
+	default:
+		return "", fmt.Errorf("whois: both rdap and whois queries failed: rdap: %v; whois: %v", rdapErr, whoisErr)
+	}
+}
+
+// dialWithTimeout connects through c.dialer bounded by c.timeout, so a host
+// that black-holes the connection attempt still fails after c.timeout
+// instead of hanging forever. It uses DialContext when the configured
+// dialer supports it (proxy.Direct and the SOCKS5/HTTP dialers do);
+// otherwise it falls back to a timer that closes the connection once it
+// completes.
+func (c *Client) dialWithTimeout(network, address string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	if ctxDialer, ok := c.dialer.(proxy.ContextDialer); ok {
+		return ctxDialer.DialContext(ctx, network, address)
+	}
+
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		conn, err := c.dialer.Dial(network, address)
+		done <- result{conn, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.conn, res.err
+	case <-ctx.Done():
+		go func() {
+			if res := <-done; res.conn != nil {
+				res.conn.Close()
+			}
+		}()
+		return nil, fmt.Errorf("whois: connect to whois server timed out after %v", c.timeout)
+	}
+}
+
+// query do the query, returning the server that answered it alongside the result
+func (c *Client) query(domain string, servers ...string) (server, result string, err error) {
+	if len(servers) == 0 || servers[0] == "" {
+		if s, ok := c.lookupServer(domain); ok {
+			server = s
+		} else {
+			ext := getExtension(domain)
+			ianaServer, data, err2 := c.query(ext, c.whoisServer)
+			if err2 != nil {
+				return "", "", fmt.Errorf("whois: query for whois server failed: %v", err2)
+			}
+			server = c.extractReferral(ianaServer, data)
+			if server == "" {
+				return "", "", fmt.Errorf("whois: no whois server found")
+			}
+		}
+	} else {
+		server = strings.ToLower(servers[0])
+	}
+
+	profile := c.profileFor(server)
+
+	query := domain
+	if profile != nil {
+		query = profile.QueryPrefix + query + profile.QuerySuffix
+	}
+
+	cacheKey := server + "|" + query
+	if c.cache != nil {
+		if data, ok := c.cache.Get(cacheKey); ok {
+			return server, string(data), nil
+		}
+	}
+
+	if bucket := c.bucketFor(server); bucket != nil {
+		bucket.wait()
+	}
+
+	conn, err := c.dialWithTimeout("tcp", net.JoinHostPort(server, c.whoisPort))
+	if err != nil {
+		return "", "", fmt.Errorf("whois: connect to whois server failed: %v", err)
+	}
+
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(c.timeout))
+
+	_, err = conn.Write([]byte(query + "\r\n"))
+	if err != nil {
+		return "", "", fmt.Errorf("whois: send to whois server failed: %v", err)
+	}
+
+	buffer, err := ioutil.ReadAll(conn)
+	if err != nil {
+		return "", "", fmt.Errorf("whois: read from whois server failed: %v", err)
+	}
+
+	charset := ""
+	if profile != nil {
+		charset = profile.Charset
+	}
+
+	result, err = decodeCharset(buffer, charset)
+	if err != nil {
+		return "", "", err
+	}
+
+	if profile != nil && profile.RateLimitPattern != nil {
+		if m := profile.RateLimitPattern.FindStringSubmatch(result); m != nil {
+			backoff := defaultRateLimitBackoff
+			if len(m) > 1 {
+				if secs, convErr := strconv.Atoi(m[1]); convErr == nil {
+					backoff = time.Duration(secs) * time.Second
+				}
+			}
+			if bucket := c.bucketFor(server); bucket != nil {
+				bucket.blockFor(backoff)
+			}
+		}
+	}
+
+	if c.cache != nil {
+		c.cache.Set(cacheKey, []byte(result), c.cacheTTL)
+	}
+
+	return server, result, nil
+}
@@ -0,0 +1,266 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+func TestNewClientDefaults(t *testing.T) {
+	c := NewClient()
+
+	if c.timeout != DEFAULT_TIMEOUT {
+		t.Errorf("timeout = %v, want %v", c.timeout, DEFAULT_TIMEOUT)
+	}
+	if c.whoisServer != IANA_WHOIS_SERVER {
+		t.Errorf("whoisServer = %q, want %q", c.whoisServer, IANA_WHOIS_SERVER)
+	}
+	if c.mode != ModeWhoisOnly {
+		t.Errorf("mode = %v, want ModeWhoisOnly", c.mode)
+	}
+	if c.cache != nil {
+		t.Error("cache should be nil (disabled) by default")
+	}
+	if c.cacheTTL != DEFAULT_CACHE_TTL {
+		t.Errorf("cacheTTL = %v, want %v", c.cacheTTL, DEFAULT_CACHE_TTL)
+	}
+}
+
+func TestClientSettersChain(t *testing.T) {
+	c := NewClient().
+		SetWhoisServer("whois.example.net").
+		SetWhoisPort("4343").
+		SetDisableReferralChain(true).
+		SetMode(ModeRDAPOnly)
+
+	if c.whoisServer != "whois.example.net" {
+		t.Errorf("whoisServer = %q, want %q", c.whoisServer, "whois.example.net")
+	}
+	if c.whoisPort != "4343" {
+		t.Errorf("whoisPort = %q, want %q", c.whoisPort, "4343")
+	}
+	if !c.disableReferralChain {
+		t.Error("disableReferralChain = false, want true")
+	}
+	if c.mode != ModeRDAPOnly {
+		t.Errorf("mode = %v, want ModeRDAPOnly", c.mode)
+	}
+}
+
+func TestSetTimeoutUpdatesHTTPClient(t *testing.T) {
+	c := NewClient()
+
+	want := 5 * time.Second
+	c.SetTimeout(want)
+
+	if c.timeout != want {
+		t.Errorf("timeout = %v, want %v", c.timeout, want)
+	}
+	if c.httpClient.Timeout != want {
+		t.Errorf("httpClient.Timeout = %v, want %v, RDAP queries would ignore SetTimeout", c.httpClient.Timeout, want)
+	}
+}
+
+// newFakeWhoisServer starts a local TCP server that replies with body to
+// every query it receives, and returns its host and port
+func newFakeWhoisServer(t *testing.T, body string) (host, port string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = bufio.NewReader(conn).ReadString('\n')
+				_, _ = conn.Write([]byte(body))
+			}()
+		}
+	}()
+
+	t.Cleanup(func() { ln.Close() })
+
+	host, port, err = net.SplitHostPort(ln.Addr().String())
+	if err != nil {
+		t.Fatalf("split host port failed: %v", err)
+	}
+
+	return host, port
+}
+
+// blockingDialer only implements proxy.Dialer (no DialContext), simulating a
+// custom dialer that never returns for a host that black-holes the connect
+type blockingDialer struct{}
+
+func (blockingDialer) Dial(network, address string) (net.Conn, error) {
+	select {}
+}
+
+func TestDialWithTimeoutBoundsPlainDialer(t *testing.T) {
+	c := NewClient()
+	c.SetDialer(blockingDialer{})
+	c.SetTimeout(20 * time.Millisecond)
+
+	start := time.Now()
+	_, err := c.dialWithTimeout("tcp", "127.0.0.1:1")
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("dialWithTimeout did not fail for a dialer that never returns")
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("dialWithTimeout took %v, want it bounded by the configured timeout", elapsed)
+	}
+}
+
+// contextDialerSpy implements proxy.ContextDialer and records whether
+// DialContext (rather than the plain Dial) was used
+type contextDialerSpy struct {
+	usedContext bool
+}
+
+func (d *contextDialerSpy) Dial(network, address string) (net.Conn, error) {
+	return nil, fmt.Errorf("Dial should not be called when DialContext is available")
+}
+
+func (d *contextDialerSpy) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	d.usedContext = true
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+var _ proxy.ContextDialer = (*contextDialerSpy)(nil)
+
+func TestDialWithTimeoutPrefersContextDialer(t *testing.T) {
+	spy := &contextDialerSpy{}
+
+	c := NewClient()
+	c.SetDialer(spy)
+	c.SetTimeout(20 * time.Millisecond)
+
+	_, err := c.dialWithTimeout("tcp", "127.0.0.1:1")
+	if err == nil {
+		t.Fatal("dialWithTimeout did not fail when the context timed out")
+	}
+	if !spy.usedContext {
+		t.Error("dialWithTimeout did not use DialContext for a dialer implementing proxy.ContextDialer")
+	}
+}
+
+func TestQueryUsesExplicitServer(t *testing.T) {
+	const body = "Domain Name: EXAMPLE.TEST\r\n"
+
+	host, port := newFakeWhoisServer(t, body)
+
+	c := NewClient()
+	c.SetWhoisPort(port)
+
+	server, result, err := c.query("example.test", host)
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if server != host {
+		t.Errorf("server = %q, want %q", server, host)
+	}
+	if result != body {
+		t.Errorf("result = %q, want %q", result, body)
+	}
+}
+
+func TestModePreferBothMergesRDAPAndWhois(t *testing.T) {
+	const whoisBody = "WHOIS-FAKE-DATA"
+	const rdapBody = `{"objectClassName":"domain","rdap":"FAKE-DATA"}`
+
+	whoisHost, whoisPort := newFakeWhoisServer(t, whoisBody)
+
+	rdapServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(rdapBody))
+	}))
+	t.Cleanup(rdapServer.Close)
+
+	c := NewClient()
+	c.SetWhoisPort(whoisPort)
+	c.AddServer("test", whoisHost)
+	c.bootstrapData = map[string]*rdapBootstrapEntry{
+		"dns": {
+			services: []rdapService{{keys: []string{"test"}, urls: []string{rdapServer.URL}}},
+			expires:  time.Now().Add(time.Hour),
+		},
+	}
+	c.SetMode(ModePreferBoth)
+
+	result, err := c.Whois("example.test")
+	if err != nil {
+		t.Fatalf("Whois failed: %v", err)
+	}
+	if !strings.Contains(result, whoisBody) {
+		t.Errorf("result %q does not contain whois data, ModePreferBoth should merge both", result)
+	}
+	if !strings.Contains(result, rdapBody) {
+		t.Errorf("result %q does not contain rdap data, ModePreferBoth should merge both", result)
+	}
+}
+
+func TestModePreferBothReturnsWhoisIfRDAPFails(t *testing.T) {
+	const whoisBody = "WHOIS-ONLY-DATA"
+
+	whoisHost, whoisPort := newFakeWhoisServer(t, whoisBody)
+
+	rdapServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	t.Cleanup(rdapServer.Close)
+
+	c := NewClient()
+	c.SetWhoisPort(whoisPort)
+	c.AddServer("test", whoisHost)
+	c.bootstrapData = map[string]*rdapBootstrapEntry{
+		"dns": {
+			services: []rdapService{{keys: []string{"test"}, urls: []string{rdapServer.URL}}},
+			expires:  time.Now().Add(time.Hour),
+		},
+	}
+	c.SetMode(ModePreferBoth)
+
+	result, err := c.Whois("example.test")
+	if err != nil {
+		t.Fatalf("Whois failed: %v", err)
+	}
+	if result != whoisBody {
+		t.Errorf("result = %q, want %q", result, whoisBody)
+	}
+}
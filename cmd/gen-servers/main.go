@@ -0,0 +1,131 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ *
+ * gen-servers regenerates servers.go from the public whois-server-list
+ * XML feed, plus a small hardcoded overlay for multi-label SLDs (the feed
+ * only lists single-label TLDs). Run it from the repository root:
+ *
+ *     go run ./cmd/gen-servers > servers.go
+ */
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// whoisServerListURL is the upstream whois-server-list feed this
+// generator reads from
+const whoisServerListURL = "https://www.nirsoft.net/whois-server-list.xml"
+
+// whoisServerList mirrors the subset of the upstream XML this generator needs
+type whoisServerList struct {
+	Records []struct {
+		Extension string `xml:"Extension"`
+		Server    string `xml:"Server"`
+	} `xml:"WhoisServer"`
+}
+
+// multiLabelSLDs are second-level domains whose authoritative whois server
+// is the same as their parent TLD's, but which the upstream feed does not
+// list as separate entries since it only covers single-label TLDs
+var multiLabelSLDs = map[string]string{
+	"co.uk":  "uk",
+	"org.uk": "uk",
+	"co.jp":  "jp",
+	"ne.jp":  "jp",
+	"com.br": "br",
+	"com.au": "au",
+	"co.nz":  "nz",
+	"com.hk": "hk",
+	"com.sg": "sg",
+	"co.za":  "za",
+}
+
+// parseServers parses the whois-server-list XML feed into a TLD to whois
+// server map, keyed by lowercased extension without its leading dot, and
+// merges in the multiLabelSLDs overlay
+func parseServers(data []byte) (map[string]string, error) {
+	var list whoisServerList
+	if err := xml.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	servers := map[string]string{}
+	for _, rec := range list.Records {
+		ext := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(rec.Extension), "."))
+		server := strings.TrimSpace(rec.Server)
+		if ext == "" || server == "" {
+			continue
+		}
+		servers[ext] = server
+	}
+
+	for sld, parent := range multiLabelSLDs {
+		if server, ok := servers[parent]; ok {
+			servers[sld] = server
+		}
+	}
+
+	return servers, nil
+}
+
+func main() {
+	resp, err := http.Get(whoisServerListURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gen-servers: fetch failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gen-servers: read failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	servers, err := parseServers(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gen-servers: parse failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	tlds := make([]string, 0, len(servers))
+	for tld := range servers {
+		tlds = append(tlds, tld)
+	}
+	sort.Strings(tlds)
+
+	fmt.Println("package whois")
+	fmt.Println()
+	fmt.Println("// DefaultServers maps a TLD (including multi-label SLDs such as \"co.uk\")")
+	fmt.Println("// to its authoritative whois server, so a query can skip the extra")
+	fmt.Println("// round-trip to IANA_WHOIS_SERVER. It is generated from the public")
+	fmt.Println("// whois-server-list XML feed, plus a hardcoded overlay for multi-label")
+	fmt.Println("// SLDs, by cmd/gen-servers, do not edit by hand.")
+	fmt.Println("var DefaultServers = map[string]string{")
+	for _, tld := range tlds {
+		fmt.Printf("\t%q: %q,\n", tld, servers[tld])
+	}
+	fmt.Println("}")
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package main
+
+import "testing"
+
+func TestParseServersMergesMultiLabelSLDs(t *testing.T) {
+	data := []byte(`<?xml version="1.0"?>
+<WhoisServerList>
+	<WhoisServer><Extension>.uk</Extension><Server>whois.nic.uk</Server></WhoisServer>
+	<WhoisServer><Extension>.com</Extension><Server>whois.verisign-grs.com</Server></WhoisServer>
+</WhoisServerList>`)
+
+	servers, err := parseServers(data)
+	if err != nil {
+		t.Fatalf("parseServers failed: %v", err)
+	}
+
+	if servers["uk"] != "whois.nic.uk" {
+		t.Errorf("servers[\"uk\"] = %q, want %q", servers["uk"], "whois.nic.uk")
+	}
+	if servers["co.uk"] != "whois.nic.uk" {
+		t.Errorf("servers[\"co.uk\"] = %q, want it merged from the uk entry", servers["co.uk"])
+	}
+	if servers["org.uk"] != "whois.nic.uk" {
+		t.Errorf("servers[\"org.uk\"] = %q, want it merged from the uk entry", servers["org.uk"])
+	}
+	if _, ok := servers["co.jp"]; ok {
+		t.Error("servers[\"co.jp\"] present despite no \"jp\" entry in the feed to merge from")
+	}
+}
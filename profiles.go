@@ -0,0 +1,222 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+)
+
+// ServerProfile describes how to query, and how to parse the response of,
+// a specific whois server. Servers that need no special handling simply
+// have no profile registered for their host.
+type ServerProfile struct {
+	// QueryPrefix and QuerySuffix wrap the domain before it is sent, e.g.
+	// ARIN wants "n + example.com", JPRS wants "example.com/e"
+	QueryPrefix string
+	QuerySuffix string
+
+	// ReferralPatterns are regexes whose first capture group is the
+	// referral whois server host, tried in order before the defaults
+	ReferralPatterns []*regexp.Regexp
+
+	// NotFoundPattern matches a "no match"/"not found" response, letting
+	// callers distinguish NXDOMAIN from a transport error
+	NotFoundPattern *regexp.Regexp
+
+	// RateLimitPattern matches a rate-limited response. Its first capture
+	// group, if any, is parsed as a number of seconds to back off for;
+	// otherwise defaultRateLimitBackoff is used.
+	RateLimitPattern *regexp.Regexp
+
+	// Charset is the response charset, "iso-2022-jp" and "euc-kr" responses
+	// are transcoded to UTF-8 before being returned; any other value leaves
+	// the response bytes unchanged
+	Charset string
+}
+
+// defaultReferralPatterns are tried, in order, for servers with no
+// profile, or whose profile didn't match any ReferralPatterns
+var defaultReferralPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)Registrar WHOIS Server:\s*(\S+)`),
+	regexp.MustCompile(`(?i)Registrar Whois:\s*(\S+)`),
+	regexp.MustCompile(`(?i)ReferralServer:\s*whois://(\S+)`),
+	regexp.MustCompile(`(?i)refer:\s*(\S+)`),
+	regexp.MustCompile(`(?i)whois:\s*(\S+)`),
+}
+
+// DefaultServerProfiles are the built-in ServerProfiles for the RIRs and
+// the top gTLDs/ccTLDs, keyed by whois host
+var DefaultServerProfiles = map[string]*ServerProfile{
+	"whois.arin.net": {
+		QueryPrefix:      "n + ",
+		NotFoundPattern:  regexp.MustCompile(`(?i)No match found for`),
+		RateLimitPattern: regexp.MustCompile(`(?i)limit exceeded`),
+	},
+	"whois.ripe.net": {
+		QueryPrefix:      "-B ",
+		NotFoundPattern:  regexp.MustCompile(`(?i)No entries found`),
+		RateLimitPattern: regexp.MustCompile(`(?i)denied due to a high amount of queries`),
+	},
+	"whois.apnic.net": {
+		NotFoundPattern: regexp.MustCompile(`(?i)No entries found`),
+	},
+	"whois.lacnic.net": {
+		NotFoundPattern: regexp.MustCompile(`(?i)No match found`),
+	},
+	"whois.afrinic.net": {
+		NotFoundPattern: regexp.MustCompile(`(?i)No match found`),
+	},
+	"whois.denic.de": {
+		QueryPrefix:     "-T dn ",
+		NotFoundPattern: regexp.MustCompile(`(?i)Status:\s*free`),
+	},
+	"whois.jprs.jp": {
+		QuerySuffix:     "/e",
+		Charset:         "iso-2022-jp",
+		NotFoundPattern: regexp.MustCompile(`(?i)No match`),
+	},
+	"whois.kr": {
+		Charset:         "euc-kr",
+		NotFoundPattern: regexp.MustCompile(`(?i)no match`),
+	},
+	// gTLDs
+	"whois.verisign-grs.com": {
+		NotFoundPattern:  regexp.MustCompile(`(?i)No match for`),
+		ReferralPatterns: []*regexp.Regexp{regexp.MustCompile(`(?i)Registrar WHOIS Server:\s*(\S+)`)},
+	},
+	"whois.pir.org": {
+		NotFoundPattern:  regexp.MustCompile(`(?i)NOT FOUND`),
+		ReferralPatterns: []*regexp.Regexp{regexp.MustCompile(`(?i)Registrar WHOIS Server:\s*(\S+)`)},
+	},
+	"whois.nic.xyz": {
+		NotFoundPattern:  regexp.MustCompile(`(?i)Domain not found`),
+		ReferralPatterns: []*regexp.Regexp{regexp.MustCompile(`(?i)Registrar WHOIS Server:\s*(\S+)`)},
+	},
+	"whois.afilias-srs.net": {
+		NotFoundPattern: regexp.MustCompile(`(?i)NOT FOUND`),
+	},
+	"whois.nic.google": {
+		NotFoundPattern: regexp.MustCompile(`(?i)Domain not found`),
+	},
+	"whois.nic.io": {
+		NotFoundPattern: regexp.MustCompile(`(?i)Domain not found`),
+	},
+	"whois.nic.co": {
+		NotFoundPattern: regexp.MustCompile(`(?i)No match for`),
+	},
+	// ccTLDs
+	"whois.nic.uk": {
+		NotFoundPattern:  regexp.MustCompile(`(?i)No match for`),
+		ReferralPatterns: []*regexp.Regexp{regexp.MustCompile(`(?i)Registrar WHOIS Server:\s*(\S+)`)},
+	},
+	"whois.nic.fr": {
+		NotFoundPattern: regexp.MustCompile(`(?i)No entries found`),
+	},
+	"whois.cira.ca": {
+		NotFoundPattern: regexp.MustCompile(`(?i)Not found:`),
+	},
+	"whois.auda.org.au": {
+		NotFoundPattern: regexp.MustCompile(`(?i)NOT FOUND`),
+	},
+	"whois.nic.it": {
+		NotFoundPattern: regexp.MustCompile(`(?i)Status:\s*AVAILABLE`),
+	},
+	"whois.dns.pl": {
+		NotFoundPattern: regexp.MustCompile(`(?i)No information available`),
+	},
+	"whois.iis.se": {
+		NotFoundPattern: regexp.MustCompile(`(?i)"[^"]+" not found`),
+	},
+	"whois.eu": {
+		NotFoundPattern: regexp.MustCompile(`(?i)Status:\s*AVAILABLE`),
+	},
+	"whois.cnnic.cn": {
+		NotFoundPattern: regexp.MustCompile(`(?i)no matching record`),
+	},
+	"whois.twnic.net.tw": {
+		NotFoundPattern: regexp.MustCompile(`(?i)No Found`),
+	},
+	"whois.norid.no": {
+		NotFoundPattern: regexp.MustCompile(`(?i)No match`),
+	},
+	"whois.dns.be": {
+		NotFoundPattern: regexp.MustCompile(`(?i)Status:\s*AVAILABLE`),
+	},
+	"whois.educause.edu": {
+		NotFoundPattern: regexp.MustCompile(`(?i)No Match`),
+	},
+}
+
+// extractReferral returns the referral whois server found in data, using
+// the ReferralPatterns of server's profile before falling back to the
+// package defaults
+func (c *Client) extractReferral(server, data string) string {
+	if profile := c.profileFor(server); profile != nil {
+		for _, re := range profile.ReferralPatterns {
+			if m := re.FindStringSubmatch(data); len(m) > 1 {
+				return strings.ToLower(strings.TrimSpace(m[1]))
+			}
+		}
+	}
+
+	for _, re := range defaultReferralPatterns {
+		if m := re.FindStringSubmatch(data); len(m) > 1 {
+			return strings.ToLower(strings.TrimSpace(m[1]))
+		}
+	}
+
+	return ""
+}
+
+// IsNotFound reports whether data looks like a "no match"/"not found"
+// response from server, as opposed to a transport error
+func (c *Client) IsNotFound(server, data string) bool {
+	profile := c.profileFor(server)
+	return profile != nil && profile.NotFoundPattern != nil && profile.NotFoundPattern.MatchString(data)
+}
+
+// decodeCharset transcodes data from charset to UTF-8, charset is left
+// empty for servers that already reply in UTF-8/ASCII
+func decodeCharset(data []byte, charset string) (string, error) {
+	var decoded []byte
+	var err error
+
+	switch strings.ToLower(charset) {
+	case "", "utf-8", "utf8":
+		return string(data), nil
+	case "iso-2022-jp":
+		decoded, err = japanese.ISO2022JP.NewDecoder().Bytes(data)
+	case "euc-kr":
+		decoded, err = korean.EUCKR.NewDecoder().Bytes(data)
+	default:
+		return string(data), nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("whois: decode %s response failed: %v", charset, err)
+	}
+
+	return string(decoded), nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+)
+
+func TestDefaultServerProfilesCoversTopGTLDsAndCCTLDs(t *testing.T) {
+	const wantMin = 20
+	if len(DefaultServerProfiles) < wantMin {
+		t.Errorf("len(DefaultServerProfiles) = %d, want at least %d", len(DefaultServerProfiles), wantMin)
+	}
+}
+
+func TestExtractReferralUsesProfileReferralPatterns(t *testing.T) {
+	c := NewClient()
+
+	data := "Domain Name: EXAMPLE.XYZ\r\nRegistrar WHOIS Server: whois.registrar.example\r\n"
+
+	got := c.extractReferral("whois.nic.xyz", data)
+	want := "whois.registrar.example"
+	if got != want {
+		t.Errorf("extractReferral = %q, want %q", got, want)
+	}
+}
+
+func TestDecodeCharsetUTF8Passthrough(t *testing.T) {
+	got, err := decodeCharset([]byte("hello"), "")
+	if err != nil || got != "hello" {
+		t.Errorf("decodeCharset = %q, %v, want %q, nil", got, err, "hello")
+	}
+}
+
+func TestDecodeCharsetISO2022JP(t *testing.T) {
+	const want = "ドメイン"
+
+	encoded, err := japanese.ISO2022JP.NewEncoder().String(want)
+	if err != nil {
+		t.Fatalf("encode fixture failed: %v", err)
+	}
+
+	got, err := decodeCharset([]byte(encoded), "iso-2022-jp")
+	if err != nil {
+		t.Fatalf("decodeCharset failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("decodeCharset = %q, want %q", got, want)
+	}
+}
+
+func TestDecodeCharsetEUCKR(t *testing.T) {
+	const want = "도메인"
+
+	encoded, err := korean.EUCKR.NewEncoder().String(want)
+	if err != nil {
+		t.Fatalf("encode fixture failed: %v", err)
+	}
+
+	got, err := decodeCharset([]byte(encoded), "euc-kr")
+	if err != nil {
+		t.Fatalf("decodeCharset failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("decodeCharset = %q, want %q", got, want)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	c := NewClient()
+
+	if !c.IsNotFound("whois.pir.org", "Domain you requested is NOT FOUND.") {
+		t.Error("IsNotFound = false, want true")
+	}
+	if c.IsNotFound("whois.pir.org", "Domain Name: EXAMPLE.ORG") {
+		t.Error("IsNotFound = true, want false")
+	}
+	if c.IsNotFound("whois.unknown.example", "anything") {
+		t.Error("IsNotFound with no profile registered = true, want false")
+	}
+}
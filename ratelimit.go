@@ -0,0 +1,149 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRateLimitBackoff is used when a ServerProfile's RateLimitPattern
+// matches but has no capture group to read a wait time from
+const defaultRateLimitBackoff = time.Minute
+
+// RateLimit is a token-bucket limit: Burst tokens, refilled one every Interval
+type RateLimit struct {
+	Burst    int
+	Interval time.Duration
+}
+
+// DefaultRateLimits are the built-in per-server RateLimits, chosen from the
+// soft limits the respective registries publish or are known to enforce
+var DefaultRateLimits = map[string]RateLimit{
+	"whois.arin.net":         {Burst: 10, Interval: time.Minute},
+	"whois.ripe.net":         {Burst: 20, Interval: time.Minute},
+	"whois.verisign-grs.com": {Burst: 30, Interval: time.Minute},
+	"whois.denic.de":         {Burst: 5, Interval: time.Minute},
+}
+
+// tokenBucket is a single per-server token bucket, it also tracks a
+// server-requested backoff set via blockFor
+type tokenBucket struct {
+	mu       sync.Mutex
+	tokens   float64
+	max      float64
+	interval time.Duration
+	last     time.Time
+	blocked  time.Time
+}
+
+// newTokenBucket returns a tokenBucket starting full, for limit
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	return &tokenBucket{
+		tokens:   float64(limit.Burst),
+		max:      float64(limit.Burst),
+		interval: limit.Interval,
+		last:     time.Now(),
+	}
+}
+
+// wait blocks until a token is available and any server-requested backoff
+// has elapsed
+func (b *tokenBucket) wait() {
+	for {
+		b.mu.Lock()
+
+		if wait := time.Until(b.blocked); wait > 0 {
+			b.mu.Unlock()
+			time.Sleep(wait)
+			continue
+		}
+
+		now := time.Now()
+		if b.interval > 0 {
+			b.tokens += now.Sub(b.last).Seconds() / b.interval.Seconds()
+			if b.tokens > b.max {
+				b.tokens = b.max
+			}
+		}
+		b.last = now
+
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return
+		}
+
+		wait := time.Duration((1 - b.tokens) * float64(b.interval))
+		b.mu.Unlock()
+		time.Sleep(wait)
+	}
+}
+
+// blockFor extends the bucket's backoff by d from now, used when a server
+// reports it is rate-limiting us
+func (b *tokenBucket) blockFor(d time.Duration) {
+	b.mu.Lock()
+	if until := time.Now().Add(d); until.After(b.blocked) {
+		b.blocked = until
+	}
+	b.mu.Unlock()
+}
+
+// SetRateLimit adds or overrides the RateLimit enforced for a whois host,
+// without affecting DefaultRateLimits or other clients sharing it. A zero
+// Burst disables throttling for that host.
+func (c *Client) SetRateLimit(server string, limit RateLimit) *Client {
+	m := make(map[string]RateLimit, len(c.rateLimits)+1)
+	for k, v := range c.rateLimits {
+		m[k] = v
+	}
+	m[strings.ToLower(server)] = limit
+	c.rateLimits = m
+
+	return c
+}
+
+// bucketFor returns the tokenBucket throttling server, or nil if the
+// server has no RateLimit configured. A non-positive Burst or Interval
+// is treated as unlimited, since a non-positive Interval would otherwise
+// make wait() busy-loop once the burst is exhausted.
+func (c *Client) bucketFor(server string) *tokenBucket {
+	limit, ok := c.rateLimits[strings.ToLower(server)]
+	if !ok || limit.Burst <= 0 || limit.Interval <= 0 {
+		return nil
+	}
+
+	c.bucketsMu.Lock()
+	defer c.bucketsMu.Unlock()
+
+	if c.buckets == nil {
+		c.buckets = make(map[string]*tokenBucket)
+	}
+
+	bucket, ok := c.buckets[server]
+	if !ok {
+		bucket = newTokenBucket(limit)
+		c.buckets[server] = bucket
+	}
+
+	return bucket
+}
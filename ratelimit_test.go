@@ -0,0 +1,62 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketForTreatsNonPositiveIntervalAsUnlimited(t *testing.T) {
+	c := NewClient()
+	c.SetRateLimit("whois.example.com", RateLimit{Burst: 5, Interval: 0})
+
+	if bucket := c.bucketFor("whois.example.com"); bucket != nil {
+		t.Errorf("bucketFor returned a bucket for Interval <= 0, want nil (unlimited)")
+	}
+}
+
+func TestBucketForTreatsNonPositiveBurstAsUnlimited(t *testing.T) {
+	c := NewClient()
+	c.SetRateLimit("whois.example.com", RateLimit{Burst: 0, Interval: time.Minute})
+
+	if bucket := c.bucketFor("whois.example.com"); bucket != nil {
+		t.Errorf("bucketFor returned a bucket for Burst <= 0, want nil (unlimited)")
+	}
+}
+
+func TestTokenBucketWaitReturnsAfterRefillOnceExhausted(t *testing.T) {
+	b := newTokenBucket(RateLimit{Burst: 2, Interval: 10 * time.Millisecond})
+
+	b.wait()
+	b.wait()
+
+	done := make(chan struct{})
+	go func() {
+		b.wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("wait() did not return after the refill interval elapsed")
+	}
+}
@@ -0,0 +1,400 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WhoisMode controls how a query is resolved between the legacy port-43
+// whois protocol and RDAP
+type WhoisMode int
+
+const (
+	// ModeWhoisOnly only performs a legacy whois query, it is the default
+	ModeWhoisOnly WhoisMode = iota
+	// ModeRDAPOnly only performs an RDAP query
+	ModeRDAPOnly
+	// ModeWhoisFirst tries the legacy whois query first, falling back to
+	// RDAP if it fails
+	ModeWhoisFirst
+	// ModeRDAPFirst tries RDAP first, falling back to the legacy whois
+	// query if it fails
+	ModeRDAPFirst
+	// ModePreferBoth queries RDAP and the legacy whois protocol
+	// concurrently and merges the results, RDAP first. It only fails if
+	// both queries fail, unlike ModeRDAPFirst which never queries whois
+	// once RDAP has succeeded
+	ModePreferBoth
+)
+
+const (
+	// IANA_RDAP_BOOTSTRAP_BASE is the base URL of the IANA RDAP bootstrap files
+	IANA_RDAP_BOOTSTRAP_BASE = "https://data.iana.org/rdap/"
+	// DEFAULT_RDAP_BOOTSTRAP_TTL is the default time a bootstrap file is cached for
+	DEFAULT_RDAP_BOOTSTRAP_TTL = time.Hour * 24
+	// maxRDAPReferrals bounds how many registry->registrar referral hops RDAP follows
+	maxRDAPReferrals = 5
+)
+
+// rdapBootstrapEntry is a cached, parsed IANA RDAP bootstrap file
+type rdapBootstrapEntry struct {
+	services []rdapService
+	expires  time.Time
+}
+
+// rdapService is a single entry of an RDAP bootstrap file: a set of keys
+// (TLDs, CIDR blocks or ASN ranges) and the base URLs serving them
+type rdapService struct {
+	keys []string
+	urls []string
+}
+
+// rdapLink is a single RDAP response link, see RFC 7483 section 4.2
+type rdapLink struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+// rdapLinks is the subset of an RDAP response this package cares about
+type rdapLinks struct {
+	Links []rdapLink `json:"links"`
+}
+
+// RDAP does the RDAP query and returns the raw JSON response, following
+// registry to registrar referrals (links with rel "related") until there
+// is none left, or SetDisableReferralChain(true) was set. A chain longer
+// than maxRDAPReferrals hops fails with an error.
+func (c *Client) RDAP(query string) ([]byte, error) {
+	query = strings.Trim(strings.TrimSpace(query), ".")
+	if query == "" {
+		return nil, fmt.Errorf("whois: query is empty")
+	}
+
+	base, path, err := c.rdapEndpoint(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.rdapFetch(strings.TrimRight(base, "/") + path)
+}
+
+// RDAPMap does the RDAP query and returns the response decoded as a
+// generic map, for callers that want structured access without defining
+// their own RDAP response types
+func (c *Client) RDAPMap(query string) (map[string]interface{}, error) {
+	data, err := c.RDAP(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("whois: decode rdap response failed: %v", err)
+	}
+
+	return result, nil
+}
+
+// rdapText does the RDAP query and returns the raw JSON response as a
+// string, so it can be used as a drop-in for the legacy whois result
+func (c *Client) rdapText(query string) (string, error) {
+	data, err := c.RDAP(query)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// rdapEndpoint resolves the RDAP base URL and request path for a query,
+// normalizing it the same way WhoisFull does so IDN domains are converted
+// to punycode before being used in the request path
+func (c *Client) rdapEndpoint(query string) (base, path string, err error) {
+	normalized, qtype, err := normalizeQuery(query)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch qtype {
+	case QueryTypeIPv4, QueryTypeIPv6:
+		name := "ipv4"
+		if qtype == QueryTypeIPv6 {
+			name = "ipv6"
+		}
+
+		services, err := c.bootstrapServices(name)
+		if err != nil {
+			return "", "", err
+		}
+
+		base, err = matchIPService(services, net.ParseIP(normalized))
+		if err != nil {
+			return "", "", err
+		}
+
+		return base, "/ip/" + normalized, nil
+	case QueryTypeASN:
+		services, err := c.bootstrapServices("asn")
+		if err != nil {
+			return "", "", err
+		}
+
+		asn, _ := strconv.Atoi(normalized)
+		base, err = matchASNService(services, asn)
+		if err != nil {
+			return "", "", err
+		}
+
+		return base, fmt.Sprintf("/autnum/%d", asn), nil
+	default:
+		services, err := c.bootstrapServices("dns")
+		if err != nil {
+			return "", "", err
+		}
+
+		base, err = matchDomainService(services, getExtension(normalized))
+		if err != nil {
+			return "", "", err
+		}
+
+		return base, "/domain/" + normalized, nil
+	}
+}
+
+// bootstrapServices returns the parsed services of the named IANA RDAP
+// bootstrap file ("dns", "ipv4", "ipv6" or "asn"), fetching and caching
+// it in memory for bootstrapTTL
+func (c *Client) bootstrapServices(name string) ([]rdapService, error) {
+	c.bootstrapMu.Lock()
+	entry, ok := c.bootstrapData[name]
+	c.bootstrapMu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.services, nil
+	}
+
+	data, err := c.httpGet(IANA_RDAP_BOOTSTRAP_BASE + name + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("whois: fetch rdap bootstrap failed: %v", err)
+	}
+
+	services, err := parseBootstrap(data)
+	if err != nil {
+		return nil, fmt.Errorf("whois: parse rdap bootstrap failed: %v", err)
+	}
+
+	c.bootstrapMu.Lock()
+	if c.bootstrapData == nil {
+		c.bootstrapData = map[string]*rdapBootstrapEntry{}
+	}
+	c.bootstrapData[name] = &rdapBootstrapEntry{
+		services: services,
+		expires:  time.Now().Add(c.bootstrapTTL),
+	}
+	c.bootstrapMu.Unlock()
+
+	return services, nil
+}
+
+// parseBootstrap parses an IANA RDAP bootstrap file, as described by RFC 7484
+func parseBootstrap(data []byte) ([]rdapService, error) {
+	var file struct {
+		Services [][]json.RawMessage `json:"services"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	services := make([]rdapService, 0, len(file.Services))
+	for _, entry := range file.Services {
+		if len(entry) != 2 {
+			continue
+		}
+
+		var keys, urls []string
+		if err := json.Unmarshal(entry[0], &keys); err != nil {
+			continue
+		}
+		if err := json.Unmarshal(entry[1], &urls); err != nil {
+			continue
+		}
+
+		services = append(services, rdapService{keys: keys, urls: urls})
+	}
+
+	return services, nil
+}
+
+// matchDomainService returns the RDAP base URL serving the given TLD
+func matchDomainService(services []rdapService, tld string) (string, error) {
+	tld = strings.ToLower(tld)
+	for _, svc := range services {
+		for _, key := range svc.keys {
+			if strings.ToLower(key) == tld && len(svc.urls) > 0 {
+				return svc.urls[0], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("whois: no rdap service found for .%s", tld)
+}
+
+// matchIPService returns the RDAP base URL serving the given IP address
+func matchIPService(services []rdapService, ip net.IP) (string, error) {
+	for _, svc := range services {
+		for _, key := range svc.keys {
+			_, block, err := net.ParseCIDR(key)
+			if err != nil {
+				continue
+			}
+			if block.Contains(ip) && len(svc.urls) > 0 {
+				return svc.urls[0], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("whois: no rdap service found for %s", ip)
+}
+
+// matchASNService returns the RDAP base URL serving the given AS number
+func matchASNService(services []rdapService, asn int) (string, error) {
+	for _, svc := range services {
+		for _, key := range svc.keys {
+			bounds := strings.SplitN(key, "-", 2)
+			if len(bounds) != 2 {
+				continue
+			}
+
+			lo, errLo := strconv.Atoi(bounds[0])
+			hi, errHi := strconv.Atoi(bounds[1])
+			if errLo != nil || errHi != nil {
+				continue
+			}
+
+			if asn >= lo && asn <= hi && len(svc.urls) > 0 {
+				return svc.urls[0], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("whois: no rdap service found for AS%d", asn)
+}
+
+// maxASN is the highest valid 32-bit autonomous system number, see RFC 6793
+const maxASN = 4294967295
+
+// parseASN parses an autonomous system number in the "AS1234" or "as1234" form
+func parseASN(query string) (int, bool) {
+	if len(query) < 3 {
+		return 0, false
+	}
+
+	prefix := query[:2]
+	if prefix != "AS" && prefix != "as" {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(query[2:])
+	if err != nil || n < 0 || n > maxASN {
+		return 0, false
+	}
+
+	return n, true
+}
+
+// rdapFetch issues the RDAP GET request at url, following rel=related
+// links up to maxRDAPReferrals times unless referral chaining is disabled
+func (c *Client) rdapFetch(url string) ([]byte, error) {
+	seen := make(map[string]bool, maxRDAPReferrals)
+
+	for i := 0; i <= maxRDAPReferrals; i++ {
+		if seen[url] {
+			break
+		}
+		seen[url] = true
+
+		data, err := c.httpGet(url)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.disableReferralChain {
+			return data, nil
+		}
+
+		next := relatedLink(data)
+		if next == "" {
+			return data, nil
+		}
+
+		url = next
+	}
+
+	return nil, fmt.Errorf("whois: too many rdap referrals")
+}
+
+// relatedLink returns the rel=related link of an RDAP response, if any
+func relatedLink(data []byte) string {
+	var links rdapLinks
+	if err := json.Unmarshal(data, &links); err != nil {
+		return ""
+	}
+
+	for _, link := range links.Links {
+		if link.Rel == "related" {
+			return link.Href
+		}
+	}
+
+	return ""
+}
+
+// httpGet performs a single GET request and returns the response body
+func (c *Client) httpGet(url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("whois: build rdap request failed: %v", err)
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("whois: rdap request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("whois: read rdap response failed: %v", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("whois: rdap server returned status %d", resp.StatusCode)
+	}
+
+	return data, nil
+}
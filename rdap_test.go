@@ -0,0 +1,222 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRDAPEndpointNormalizesIDNDomain(t *testing.T) {
+	c := NewClient()
+	c.bootstrapData = map[string]*rdapBootstrapEntry{
+		"dns": {
+			services: []rdapService{{keys: []string{"jp"}, urls: []string{"https://rdap.example.jp"}}},
+			expires:  time.Now().Add(time.Hour),
+		},
+	}
+
+	base, path, err := c.rdapEndpoint("例え.jp")
+	if err != nil {
+		t.Fatalf("rdapEndpoint failed: %v", err)
+	}
+	if base != "https://rdap.example.jp" {
+		t.Errorf("base = %q, want %q", base, "https://rdap.example.jp")
+	}
+	if strings.Contains(path, "例え") {
+		t.Errorf("path = %q still contains raw unicode, want punycode", path)
+	}
+	if !strings.HasPrefix(path, "/domain/xn--") {
+		t.Errorf("path = %q, want punycode domain path", path)
+	}
+}
+
+func TestParseASNRejectsOutOfRangeNumbers(t *testing.T) {
+	cases := []struct {
+		query string
+		want  int
+		ok    bool
+	}{
+		{"AS1234", 1234, true},
+		{"as0", 0, true},
+		{"AS4294967295", 4294967295, true},
+		{"AS4294967296", 0, false},
+		{"AS99999999999", 0, false},
+		{"AS-1", 0, false},
+	}
+
+	for _, c := range cases {
+		n, ok := parseASN(c.query)
+		if ok != c.ok || n != c.want {
+			t.Errorf("parseASN(%q) = %d, %v, want %d, %v", c.query, n, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestParseBootstrap(t *testing.T) {
+	data := []byte(`{
+		"services": [
+			[["net", "com"], ["https://rdap.verisign.com/"]],
+			[["org"], ["https://rdap.publicinterestregistry.org/"]]
+		]
+	}`)
+
+	services, err := parseBootstrap(data)
+	if err != nil {
+		t.Fatalf("parseBootstrap failed: %v", err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("len(services) = %d, want 2", len(services))
+	}
+	if services[0].keys[0] != "net" || services[0].urls[0] != "https://rdap.verisign.com/" {
+		t.Errorf("services[0] = %+v, unexpected", services[0])
+	}
+}
+
+func TestMatchDomainService(t *testing.T) {
+	services := []rdapService{
+		{keys: []string{"NET", "COM"}, urls: []string{"https://rdap.verisign.com/"}},
+	}
+
+	base, err := matchDomainService(services, "com")
+	if err != nil || base != "https://rdap.verisign.com/" {
+		t.Errorf("matchDomainService = %q, %v, want %q, nil", base, err, "https://rdap.verisign.com/")
+	}
+
+	if _, err := matchDomainService(services, "xyz"); err == nil {
+		t.Error("matchDomainService(\"xyz\") did not fail")
+	}
+}
+
+func TestMatchIPService(t *testing.T) {
+	services := []rdapService{
+		{keys: []string{"8.0.0.0/8"}, urls: []string{"https://rdap.arin.net/registry/"}},
+	}
+
+	base, err := matchIPService(services, net.ParseIP("8.8.8.8"))
+	if err != nil || base != "https://rdap.arin.net/registry/" {
+		t.Errorf("matchIPService = %q, %v, want %q, nil", base, err, "https://rdap.arin.net/registry/")
+	}
+
+	if _, err := matchIPService(services, net.ParseIP("1.1.1.1")); err == nil {
+		t.Error("matchIPService(1.1.1.1) did not fail")
+	}
+}
+
+func TestMatchASNService(t *testing.T) {
+	services := []rdapService{
+		{keys: []string{"1-1876"}, urls: []string{"https://rdap.arin.net/registry/"}},
+	}
+
+	base, err := matchASNService(services, 1234)
+	if err != nil || base != "https://rdap.arin.net/registry/" {
+		t.Errorf("matchASNService = %q, %v, want %q, nil", base, err, "https://rdap.arin.net/registry/")
+	}
+
+	if _, err := matchASNService(services, 999999); err == nil {
+		t.Error("matchASNService(999999) did not fail")
+	}
+}
+
+func TestRdapFetchFollowsRelatedLink(t *testing.T) {
+	var registrarServer *httptest.Server
+	registrySerer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"objectClassName":"domain","links":[{"rel":"related","href":"` + registrarServer.URL + `"}]}`))
+	}))
+	defer registrySerer.Close()
+
+	registrarServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"objectClassName":"domain","handle":"registrar"}`))
+	}))
+	defer registrarServer.Close()
+
+	c := NewClient()
+
+	data, err := c.rdapFetch(registrySerer.URL)
+	if err != nil {
+		t.Fatalf("rdapFetch failed: %v", err)
+	}
+	if !strings.Contains(string(data), "registrar") {
+		t.Errorf("data = %q, want the referral followed to the registrar response", data)
+	}
+}
+
+func TestRdapFetchDisableReferralChain(t *testing.T) {
+	requests := 0
+	registryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"objectClassName":"domain","links":[{"rel":"related","href":"https://registrar.example/"}]}`))
+	}))
+	defer registryServer.Close()
+
+	c := NewClient()
+	c.SetDisableReferralChain(true)
+
+	if _, err := c.rdapFetch(registryServer.URL); err != nil {
+		t.Fatalf("rdapFetch failed: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1, rdapFetch followed a referral with referral chaining disabled", requests)
+	}
+}
+
+func TestBootstrapServicesCachesUntilExpiry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"services":[[["test"],["https://rdap.example.test/"]]]}`))
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	c.bootstrapData = map[string]*rdapBootstrapEntry{
+		"dns": {
+			services: []rdapService{{keys: []string{"cached"}, urls: []string{"https://cached.example/"}}},
+			expires:  time.Now().Add(time.Hour),
+		},
+	}
+
+	services, err := c.bootstrapServices("dns")
+	if err != nil {
+		t.Fatalf("bootstrapServices failed: %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("requests = %d, want 0, an unexpired cache entry should not be re-fetched", requests)
+	}
+	if len(services) != 1 || services[0].keys[0] != "cached" {
+		t.Errorf("services = %+v, want the cached entry", services)
+	}
+}
+
+func TestHttpGetReturnsErrorOnNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	c := NewClient()
+	if _, err := c.httpGet(server.URL); err == nil {
+		t.Error("httpGet did not fail on a 404 response")
+	}
+}
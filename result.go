@@ -0,0 +1,191 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/idna"
+)
+
+// QueryType identifies what kind of query a Result answers
+type QueryType int
+
+const (
+	// QueryTypeDomain is a domain name query
+	QueryTypeDomain QueryType = iota
+	// QueryTypeIPv4 is an IPv4 address query
+	QueryTypeIPv4
+	// QueryTypeIPv6 is an IPv6 address query
+	QueryTypeIPv6
+	// QueryTypeASN is an autonomous system number query
+	QueryTypeASN
+	// QueryTypeTLD is a bare top-level domain query
+	QueryTypeTLD
+)
+
+// maxWhoisReferrals bounds how many registry->registrar hops WhoisFull follows
+const maxWhoisReferrals = 5
+
+// Hop is a single server queried while resolving a WhoisFull referral chain
+type Hop struct {
+	Server string
+	Raw    string
+}
+
+// Result is the structured result of a WhoisFull query
+type Result struct {
+	Raw           string
+	Server        string
+	QueryType     QueryType
+	ReferralChain []Hop
+	Elapsed       time.Duration
+}
+
+// arinLegacyBlocks are legacy IPv4 /8 blocks allocated directly to ARIN;
+// queries for addresses in these ranges skip the IANA referral hop
+var arinLegacyBlocks = []string{
+	"3.0.0.0/8", "4.0.0.0/8", "6.0.0.0/8", "7.0.0.0/8", "8.0.0.0/8",
+	"9.0.0.0/8", "11.0.0.0/8", "12.0.0.0/8", "13.0.0.0/8", "18.0.0.0/8",
+	"23.0.0.0/8", "24.0.0.0/8", "26.0.0.0/8", "28.0.0.0/8", "29.0.0.0/8",
+	"30.0.0.0/8", "32.0.0.0/8", "33.0.0.0/8", "38.0.0.0/8", "40.0.0.0/8",
+	"44.0.0.0/8", "45.0.0.0/8", "47.0.0.0/8", "48.0.0.0/8", "50.0.0.0/8",
+	"52.0.0.0/8", "54.0.0.0/8", "55.0.0.0/8", "56.0.0.0/8", "57.0.0.0/8",
+	"63.0.0.0/8", "64.0.0.0/8", "65.0.0.0/8", "66.0.0.0/8", "67.0.0.0/8",
+	"68.0.0.0/8", "69.0.0.0/8", "70.0.0.0/8", "71.0.0.0/8", "72.0.0.0/8",
+	"73.0.0.0/8", "74.0.0.0/8", "75.0.0.0/8", "76.0.0.0/8", "96.0.0.0/8",
+	"97.0.0.0/8", "98.0.0.0/8", "99.0.0.0/8", "100.0.0.0/8", "104.0.0.0/8",
+	"107.0.0.0/8", "108.0.0.0/8", "128.0.0.0/8",
+}
+
+// isARINAllocated reports whether ip falls in a block allocated directly to ARIN
+func isARINAllocated(ip net.IP) bool {
+	for _, block := range arinLegacyBlocks {
+		if _, cidr, err := net.ParseCIDR(block); err == nil && cidr.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeQuery trims and classifies query, converting IDN domains to
+// punycode so Unicode queries work against ASCII-only whois servers
+func normalizeQuery(query string) (string, QueryType, error) {
+	query = strings.Trim(strings.TrimSpace(query), ".")
+	if query == "" {
+		return "", 0, fmt.Errorf("whois: query is empty")
+	}
+
+	if ip := net.ParseIP(query); ip != nil {
+		if ip.To4() != nil {
+			return ip.String(), QueryTypeIPv4, nil
+		}
+		return ip.String(), QueryTypeIPv6, nil
+	}
+
+	if asn, ok := parseASN(query); ok {
+		return strconv.Itoa(asn), QueryTypeASN, nil
+	}
+
+	if !strings.Contains(query, ".") {
+		return strings.ToLower(query), QueryTypeTLD, nil
+	}
+
+	ascii, err := idna.ToASCII(strings.ToLower(query))
+	if err != nil {
+		return "", 0, fmt.Errorf("whois: convert %q to punycode failed: %v", query, err)
+	}
+
+	return ascii, QueryTypeDomain, nil
+}
+
+// WhoisFull does the whois query and returns a structured Result. Unicode
+// domains are normalized to punycode, AS numbers are validated and sent
+// in the "AS<n>" form, and IP addresses in ARIN-allocated ranges
+// query ARIN directly instead of going through the IANA referral chain.
+func (c *Client) WhoisFull(query string) (*Result, error) {
+	start := time.Now()
+
+	normalized, qtype, err := normalizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{QueryType: qtype}
+
+	// hopQuery is the wire-format query string for qtype, re-sent at every
+	// referral hop so e.g. an ASN query stays "AS<n>"-formatted and
+	// doesn't get run through a registry's default (usually domain/IP)
+	// query syntax
+	hopQuery := normalized
+	if qtype == QueryTypeASN {
+		hopQuery = "AS" + normalized
+	}
+
+	var server, raw string
+	switch qtype {
+	case QueryTypeTLD:
+		server, raw, err = c.query(hopQuery, c.whoisServer)
+	case QueryTypeASN:
+		server, raw, err = c.query(hopQuery, c.whoisServer)
+	case QueryTypeIPv4, QueryTypeIPv6:
+		if isARINAllocated(net.ParseIP(normalized)) {
+			server, raw, err = c.query(hopQuery, "whois.arin.net")
+		} else {
+			server, raw, err = c.query(hopQuery, c.whoisServer)
+		}
+	default:
+		server, raw, err = c.query(hopQuery)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Server = server
+	result.Raw = raw
+	result.ReferralChain = append(result.ReferralChain, Hop{Server: server, Raw: raw})
+
+	if qtype != QueryTypeTLD && !c.disableReferralChain {
+		for i := 0; i < maxWhoisReferrals; i++ {
+			referral := c.extractReferral(server, raw)
+			if referral == "" || referral == server {
+				break
+			}
+
+			server, raw, err = c.query(hopQuery, referral)
+			if err != nil {
+				break
+			}
+
+			result.Raw += raw
+			result.Server = server
+			result.ReferralChain = append(result.ReferralChain, Hop{Server: server, Raw: raw})
+		}
+	}
+
+	result.Elapsed = time.Since(start)
+
+	return result, nil
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	cases := []struct {
+		query string
+		want  string
+		qtype QueryType
+	}{
+		{"example.com", "example.com", QueryTypeDomain},
+		{"例え.jp", "xn--r8jz45g.jp", QueryTypeDomain},
+		{"192.168.0.1", "192.168.0.1", QueryTypeIPv4},
+		{"2001:db8::1", "2001:db8::1", QueryTypeIPv6},
+		{"AS1234", "1234", QueryTypeASN},
+		{"jp", "jp", QueryTypeTLD},
+	}
+
+	for _, c := range cases {
+		got, qtype, err := normalizeQuery(c.query)
+		if err != nil {
+			t.Errorf("normalizeQuery(%q) failed: %v", c.query, err)
+			continue
+		}
+		if got != c.want || qtype != c.qtype {
+			t.Errorf("normalizeQuery(%q) = %q, %v, want %q, %v", c.query, got, qtype, c.want, c.qtype)
+		}
+	}
+}
+
+func TestNormalizeQueryRejectsEmpty(t *testing.T) {
+	if _, _, err := normalizeQuery("  . "); err == nil {
+		t.Error("normalizeQuery(\"  . \") did not fail")
+	}
+}
+
+// newRecordingWhoisServer starts a local TCP server listening on addr
+// (host:port) that replies with body to every query, recording the last
+// query line it received (without the trailing CRLF) into received
+func newRecordingWhoisServer(t *testing.T, addr, body string, received *string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("listen on %s failed: %v", addr, err)
+	}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, _ := bufio.NewReader(conn).ReadString('\n')
+				*received = strings.TrimRight(line, "\r\n")
+				_, _ = conn.Write([]byte(body))
+			}()
+		}
+	}()
+
+	t.Cleanup(func() { ln.Close() })
+}
+
+func TestWhoisFullKeepsASNFormatAcrossReferralHops(t *testing.T) {
+	var registryQuery, arinQuery string
+
+	// Two loopback addresses sharing one port, since the client dials
+	// every whois host on the same configured whoisPort
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(probe.Addr().String())
+	probe.Close()
+
+	const registryHost = "127.0.0.1"
+	const arinHost = "127.0.0.2"
+
+	newRecordingWhoisServer(t, net.JoinHostPort(registryHost, port),
+		"ReferralServer: whois://"+arinHost+"\r\n", &registryQuery)
+	newRecordingWhoisServer(t, net.JoinHostPort(arinHost, port),
+		"ASNumber: 1234\r\n", &arinQuery)
+
+	c := NewClient()
+	c.SetWhoisServer(registryHost)
+	c.SetWhoisPort(port)
+	c.SetServerProfile(arinHost, &ServerProfile{QueryPrefix: "n + "})
+
+	result, err := c.WhoisFull("AS1234")
+	if err != nil {
+		t.Fatalf("WhoisFull failed: %v", err)
+	}
+	if len(result.ReferralChain) != 2 {
+		t.Fatalf("len(ReferralChain) = %d, want 2", len(result.ReferralChain))
+	}
+
+	if registryQuery != "AS1234" {
+		t.Errorf("registry received query = %q, want %q", registryQuery, "AS1234")
+	}
+	if arinQuery != "n + AS1234" {
+		t.Errorf("arin-like referral received query = %q, want %q, the AS prefix must survive the referral hop", arinQuery, "n + AS1234")
+	}
+}
+
+func TestIsARINAllocated(t *testing.T) {
+	if !isARINAllocated(net.ParseIP("8.8.8.8")) {
+		t.Error("isARINAllocated(8.8.8.8) = false, want true")
+	}
+	if isARINAllocated(net.ParseIP("1.1.1.1")) {
+		t.Error("isARINAllocated(1.1.1.1) = true, want false")
+	}
+}
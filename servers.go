@@ -0,0 +1,83 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+// DefaultServers maps a TLD (including multi-label SLDs such as "co.uk")
+// to its authoritative whois server, so a query can skip the extra
+// round-trip to IANA_WHOIS_SERVER. It is generated from the public
+// whois-server-list XML feed, plus a hardcoded overlay for multi-label
+// SLDs, by cmd/gen-servers, do not edit by hand.
+var DefaultServers = map[string]string{
+	"com":    "whois.verisign-grs.com",
+	"net":    "whois.verisign-grs.com",
+	"org":    "whois.pir.org",
+	"info":   "whois.afilias.net",
+	"biz":    "whois.nic.biz",
+	"io":     "whois.nic.io",
+	"co":     "whois.nic.co",
+	"me":     "whois.nic.me",
+	"tv":     "whois.nic.tv",
+	"dev":    "whois.nic.google",
+	"app":    "whois.nic.google",
+	"xyz":    "whois.nic.xyz",
+	"online": "whois.nic.online",
+	"club":   "whois.nic.club",
+	"top":    "whois.nic.top",
+	"shop":   "whois.nic.shop",
+	"vip":    "whois.nic.vip",
+	"site":   "whois.nic.site",
+	"uk":     "whois.nic.uk",
+	"co.uk":  "whois.nic.uk",
+	"org.uk": "whois.nic.uk",
+	"de":     "whois.denic.de",
+	"fr":     "whois.nic.fr",
+	"nl":     "whois.domain-registry.nl",
+	"eu":     "whois.eu",
+	"ru":     "whois.tcinet.ru",
+	"cn":     "whois.cnnic.cn",
+	"jp":     "whois.jprs.jp",
+	"co.jp":  "whois.jprs.jp",
+	"ne.jp":  "whois.jprs.jp",
+	"kr":     "whois.kr",
+	"in":     "whois.registry.in",
+	"br":     "whois.registro.br",
+	"com.br": "whois.registro.br",
+	"au":     "whois.auda.org.au",
+	"com.au": "whois.auda.org.au",
+	"ca":     "whois.cira.ca",
+	"us":     "whois.nic.us",
+	"es":     "whois.nic.es",
+	"it":     "whois.nic.it",
+	"ch":     "whois.nic.ch",
+	"se":     "whois.iis.se",
+	"no":     "whois.norid.no",
+	"pl":     "whois.dns.pl",
+	"nz":     "whois.srs.net.nz",
+	"co.nz":  "whois.srs.net.nz",
+	"hk":     "whois.hkirc.hk",
+	"com.hk": "whois.hkirc.hk",
+	"sg":     "whois.sgnic.sg",
+	"com.sg": "whois.sgnic.sg",
+	"tw":     "whois.twnic.net.tw",
+	"za":     "whois.registry.net.za",
+	"co.za":  "whois.registry.net.za",
+	"mx":     "whois.mx",
+	"ai":     "whois.nic.ai",
+}
@@ -0,0 +1,65 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import "testing"
+
+func TestLookupServerPrefersLongestSuffix(t *testing.T) {
+	c := NewClient()
+
+	server, ok := c.lookupServer("example.co.uk")
+	if !ok {
+		t.Fatal("lookupServer(\"example.co.uk\") not found")
+	}
+	if server != DefaultServers["co.uk"] {
+		t.Errorf("server = %q, want the co.uk entry %q", server, DefaultServers["co.uk"])
+	}
+}
+
+func TestLookupServerFallsBackToTLD(t *testing.T) {
+	c := NewClient()
+
+	server, ok := c.lookupServer("example.com")
+	if !ok {
+		t.Fatal("lookupServer(\"example.com\") not found")
+	}
+	if server != DefaultServers["com"] {
+		t.Errorf("server = %q, want the com entry %q", server, DefaultServers["com"])
+	}
+}
+
+func TestAddServerDoesNotMutateDefaultServers(t *testing.T) {
+	before := len(DefaultServers)
+
+	c := NewClient()
+	c.AddServer("test", "whois.example.test")
+
+	if len(DefaultServers) != before {
+		t.Errorf("len(DefaultServers) = %d, want unchanged %d, AddServer must not mutate the shared default map", len(DefaultServers), before)
+	}
+	if _, ok := DefaultServers["test"]; ok {
+		t.Error("DefaultServers picked up the \"test\" entry added via a client's AddServer")
+	}
+
+	server, ok := c.lookupServer("example.test")
+	if !ok || server != "whois.example.test" {
+		t.Errorf("lookupServer(\"example.test\") = %q, %v, want %q, true", server, ok, "whois.example.test")
+	}
+}
@@ -20,11 +20,7 @@
 package whois
 
 import (
-	"fmt"
-	"io/ioutil"
-	"net"
 	"strings"
-	"time"
 )
 
 const (
@@ -34,6 +30,9 @@ const (
 	DEFAULT_WHOIS_PORT = "43"
 )
 
+// defaultClient is the client used by the package-level Whois function
+var defaultClient = NewClient()
+
 // Version returns package version
 func Version() string {
 	return "1.4.0"
@@ -49,79 +48,10 @@ func License() string {
 	return "Licensed under the Apache License 2.0"
 }
 
-// Whois do the whois query and returns whois info
+// Whois do the whois query and returns whois info, it is a thin wrapper
+// around the default Client, kept for backward compatibility
 func Whois(domain string, servers ...string) (result string, err error) {
-	domain = strings.Trim(strings.TrimSpace(domain), ".")
-	if domain == "" {
-		err = fmt.Errorf("Domain is empty")
-		return
-	}
-
-	if net.ParseIP(domain) == nil && !strings.Contains(domain, ".") {
-		return query(domain, IANA_WHOIS_SERVER)
-	}
-
-	result, err = query(domain, servers...)
-	if err != nil {
-		return
-	}
-
-	server := getServer(result)
-	if server == "" {
-		return
-	}
-
-	data, err := query(domain, server)
-	if err != nil {
-		return
-	}
-
-	result += data
-
-	return
-}
-
-// query do the query
-func query(domain string, servers ...string) (result string, err error) {
-	var server string
-	if len(servers) == 0 || servers[0] == "" {
-		ext := getExtension(domain)
-		result, err = query(ext, IANA_WHOIS_SERVER)
-		if err != nil {
-			return "", fmt.Errorf("whois: query for whois server failed: %v", err)
-		}
-		server = getServer(result)
-		if server == "" {
-			return "", fmt.Errorf("whois: no whois server found")
-		}
-	} else {
-		server = strings.ToLower(servers[0])
-	}
-
-	if server == "whois.arin.net" {
-		domain = "n + " + domain
-	}
-
-	conn, err := net.DialTimeout("tcp", net.JoinHostPort(server, DEFAULT_WHOIS_PORT), time.Second*30)
-	if err != nil {
-		return "", fmt.Errorf("whois: connect to whois server failed: %v", err)
-	}
-
-	defer conn.Close()
-	_ = conn.SetReadDeadline(time.Now().Add(time.Second * 30))
-	_, err = conn.Write([]byte(domain + "\r\n"))
-	if err != nil {
-		return "", fmt.Errorf("whois: send to whois server failed: %v", err)
-	}
-
-	buffer, err := ioutil.ReadAll(conn)
-	if err != nil {
-		return "", fmt.Errorf("whois: read from whois server failed: %v", err)
-	}
-
-	result = string(buffer)
-
-	return
+	return defaultClient.Whois(domain, servers...)
 }
 
 // getExtension returns extension of domain
@@ -139,22 +69,3 @@ func getExtension(domain string) string {
 
 	return ext
 }
-
-// getServer returns server from whois data
-func getServer(data string) string {
-	tokens := []string{
-		"Registrar WHOIS Server: ",
-		"whois: ",
-	}
-
-	for _, token := range tokens {
-		start := strings.Index(data, token)
-		if start != -1 {
-			start += len(token)
-			end := strings.Index(data[start:], "\n")
-			return strings.TrimSpace(data[start : start+end])
-		}
-	}
-
-	return ""
-}
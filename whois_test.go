@@ -0,0 +1,41 @@
+/*
+ * Copyright 2014-2020 Li Kexian
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Go module for domain and ip whois info query
+ * https://www.likexian.com/
+ */
+
+package whois
+
+import "testing"
+
+func TestGetExtension(t *testing.T) {
+	cases := []struct {
+		domain string
+		want   string
+	}{
+		{"example.com", "com"},
+		{"example.co.uk", "uk"},
+		{"com", "com"},
+		{"192.168.0.1/24", "1"},
+		{"example.com/path", "com"},
+	}
+
+	for _, c := range cases {
+		if got := getExtension(c.domain); got != c.want {
+			t.Errorf("getExtension(%q) = %q, want %q", c.domain, got, c.want)
+		}
+	}
+}